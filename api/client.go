@@ -1,7 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 
 	"go.sia.tech/core/consensus"
 	"go.sia.tech/core/types"
@@ -16,12 +23,18 @@ type Client struct {
 
 // TxpoolBroadcast broadcasts a transaction to the network.
 func (c *Client) TxpoolBroadcast(txn types.Transaction, dependsOn []types.Transaction) (err error) {
-	err = c.c.POST("/txpool/broadcast", TxpoolBroadcastRequest{dependsOn, txn}, nil)
+	err = c.c.POST("/txpool/broadcast", TxpoolBroadcastRequest{DependsOn: dependsOn, Transaction: &txn}, nil)
+	return
+}
+
+// TxpoolBroadcastV2 broadcasts a v2 transaction to the network.
+func (c *Client) TxpoolBroadcastV2(txn types.V2Transaction, dependsOn []types.V2Transaction) (err error) {
+	err = c.c.POST("/txpool/broadcast", TxpoolBroadcastRequest{V2DependsOn: dependsOn, V2Transaction: &txn}, nil)
 	return
 }
 
 // TxpoolTransactions returns all transactions in the transaction pool.
-func (c *Client) TxpoolTransactions() (resp []types.Transaction, err error) {
+func (c *Client) TxpoolTransactions() (resp TxpoolTransactionsResponse, err error) {
 	err = c.c.GET("/txpool/transactions", &resp)
 	return
 }
@@ -68,6 +81,12 @@ func (c *Client) FileContractElement(id types.ElementID) (resp types.FileContrac
 	return
 }
 
+// V2FileContractElement returns the v2 file contract element with the given ID.
+func (c *Client) V2FileContractElement(id types.ElementID) (resp types.V2FileContractElement, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/element/v2contract/%s", id), &resp)
+	return
+}
+
 // ElementSearch returns information about a given element.
 func (c *Client) ElementSearch(id types.ElementID) (resp ExplorerSearchResponse, err error) {
 	err = c.c.GET(fmt.Sprintf("/explorer/element/search/%s", id), &resp)
@@ -80,6 +99,13 @@ func (c *Client) AddressBalance(address types.Address) (resp ExplorerWalletBalan
 	return
 }
 
+// AddressBalanceAt returns the siacoin and siafund balance of an address as
+// of the given chain index, or the current tip if index is the zero value.
+func (c *Client) AddressBalanceAt(address types.Address, index types.ChainIndex) (resp ExplorerWalletBalanceAtResponse, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/address/%s/balance/%s", address, indexOrTip(index)), &resp)
+	return
+}
+
 // SiacoinOutputs returns the unspent siacoin elements of an address.
 func (c *Client) SiacoinOutputs(address types.Address) (resp []types.ElementID, err error) {
 	err = c.c.GET(fmt.Sprintf("/explorer/address/%s/siacoins", address), &resp)
@@ -92,9 +118,66 @@ func (c *Client) SiafundOutputs(address types.Address) (resp []types.ElementID,
 	return
 }
 
-// Transactions returns the latest transaction IDs the address was involved in.
-func (c *Client) Transactions(address types.Address, amount, offset int) (resp []types.TransactionID, err error) {
-	err = c.c.GET(fmt.Sprintf("/explorer/address/%s/transactions?amount=%d&offset=%d", address, amount, offset), &resp)
+// SiacoinOutputsAt returns the siacoin elements an address had unspent as of
+// the given chain index, or the current tip if index is the zero value.
+func (c *Client) SiacoinOutputsAt(address types.Address, index types.ChainIndex) (resp ExplorerElementsAtResponse, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/address/%s/siacoins/%s", address, indexOrTip(index)), &resp)
+	return
+}
+
+// SiafundOutputsAt returns the siafund elements an address had unspent as of
+// the given chain index, or the current tip if index is the zero value.
+func (c *Client) SiafundOutputsAt(address types.Address, index types.ChainIndex) (resp ExplorerElementsAtResponse, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/address/%s/siafunds/%s", address, indexOrTip(index)), &resp)
+	return
+}
+
+// indexOrTip returns the path segment for a chain index query, treating the
+// zero value as a request for the current tip.
+func indexOrTip(index types.ChainIndex) string {
+	if index == (types.ChainIndex{}) {
+		return "tip"
+	}
+	return index.String()
+}
+
+// Transactions returns a page of transaction IDs the address was involved
+// in, matching query.
+func (c *Client) Transactions(address types.Address, query TransactionQuery) (resp TransactionPage, err error) {
+	q := url.Values{}
+	if query.SinceHeight != 0 {
+		q.Set("sinceHeight", strconv.FormatUint(query.SinceHeight, 10))
+	}
+	if query.UntilHeight != 0 {
+		q.Set("untilHeight", strconv.FormatUint(query.UntilHeight, 10))
+	}
+	if query.SinceTime != 0 {
+		q.Set("sinceTime", strconv.FormatInt(query.SinceTime, 10))
+	}
+	if query.UntilTime != 0 {
+		q.Set("untilTime", strconv.FormatInt(query.UntilTime, 10))
+	}
+	if query.MinAmount != (types.Currency{}) {
+		q.Set("minAmount", query.MinAmount.String())
+	}
+	if query.MaxAmount != (types.Currency{}) {
+		q.Set("maxAmount", query.MaxAmount.String())
+	}
+	if query.Kind != TransactionKindAny {
+		q.Set("kind", string(query.Kind))
+	}
+	if query.Limit != 0 {
+		q.Set("limit", strconv.Itoa(query.Limit))
+	}
+	if query.Cursor != nil {
+		buf, err := json.Marshal(query.Cursor)
+		if err != nil {
+			return TransactionPage{}, err
+		}
+		q.Set("cursor", string(buf))
+	}
+
+	err = c.c.GET(fmt.Sprintf("/explorer/address/%s/transactions?%s", address, q.Encode()), &resp)
 	return
 }
 
@@ -104,6 +187,19 @@ func (c *Client) Transaction(id types.TransactionID) (resp types.Transaction, er
 	return
 }
 
+// V2Transaction returns a v2 transaction with the given ID.
+func (c *Client) V2Transaction(id types.TransactionID) (resp types.V2Transaction, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/v2/transaction/%s", id), &resp)
+	return
+}
+
+// V2TransactionAttachments returns the attestations and arbitrary data
+// attached to a v2 transaction with the given ID.
+func (c *Client) V2TransactionAttachments(id types.TransactionID) (resp V2TransactionAttachmentsResponse, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/v2/transaction/%s/attachments", id), &resp)
+	return
+}
+
 // BatchBalance returns the siacoin and siafund balance of a list of addresses.
 func (c *Client) BatchBalance(addresses []types.Address) (resp []ExplorerWalletBalanceResponse, err error) {
 	err = c.c.POST("/explorer/batch/addresses/balance", addresses, &resp)
@@ -122,12 +218,121 @@ func (c *Client) BatchSiafunds(addresses []types.Address) (resp [][]types.Siafun
 	return
 }
 
-// BatchTransactions returns the last n transactions of the addresses.
-func (c *Client) BatchTransactions(addresses []ExplorerTransactionsRequest) (resp [][]types.Transaction, err error) {
+// BatchTransactions returns a page of legacy and v2 transactions for each
+// requested address, matching each request's query. Passing the cursors
+// from a previous response back in lets a caller poll many addresses and
+// receive only new activity since the last call.
+func (c *Client) BatchTransactions(addresses []ExplorerTransactionsRequest) (resp []ExplorerBatchTransactionsResponse, err error) {
 	err = c.c.POST("/explorer/batch/addresses/transactions", addresses, &resp)
 	return
 }
 
+// RichList returns the n addresses with the largest siacoin balance.
+func (c *Client) RichList(n int) (resp []RichListEntry, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/stats/richlist?limit=%d", n), &resp)
+	return
+}
+
+// SupplyDistribution returns the distribution of siacoin balances across
+// addresses, bucketed by bounds, along with the Gini coefficient of the
+// resulting distribution.
+func (c *Client) SupplyDistribution(bounds []types.Currency) (resp ExplorerDistributionResponse, err error) {
+	buf, err := json.Marshal(bounds)
+	if err != nil {
+		return ExplorerDistributionResponse{}, err
+	}
+	err = c.c.GET(fmt.Sprintf("/explorer/stats/distribution?bounds=%s", url.QueryEscape(string(buf))), &resp)
+	return
+}
+
+// AddressesForUnlockHash returns every address the explorer has observed
+// that resolves to the given unlock hash template.
+func (c *Client) AddressesForUnlockHash(h types.Hash256) (resp []types.Address, err error) {
+	err = c.c.GET(fmt.Sprintf("/explorer/stats/unlockhash/%s", h), &resp)
+	return
+}
+
+// Subscribe opens a streaming subscription for the given categories,
+// optionally resuming from a previous ResumeToken, and returns a channel of
+// Events along with a function to close the subscription. The channel is
+// closed once the subscription ends, whether due to the caller invoking the
+// returned close function or the connection being dropped.
+//
+// Unlike the rest of Client's methods, Subscribe can't go through c.c's
+// GET/POST helpers: those decode a single JSON response body and return,
+// whereas this endpoint holds the connection open and streams a sequence of
+// JSON values over time. It still authenticates and resolves URLs the same
+// way c.c does, to stay consistent if that ever changes.
+func (c *Client) Subscribe(categories []string, since *ResumeToken) (<-chan Event, func(), error) {
+	q := url.Values{"category": categories}
+	if since != nil {
+		buf, err := json.Marshal(since)
+		if err != nil {
+			return nil, nil, err
+		}
+		q.Set("since", string(buf))
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/explorer/subscribe?%s", c.c.BaseURL, q.Encode()), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.SetBasicAuth("", c.c.Password)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		buf, _ := io.ReadAll(resp.Body)
+		return nil, nil, fmt.Errorf("subscribe failed: %s", strings.TrimSpace(string(buf)))
+	}
+
+	done := make(chan struct{})
+	var closeOnce sync.Once
+	cancel := func() {
+		closeOnce.Do(func() {
+			close(done)
+			resp.Body.Close()
+		})
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer cancel()
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var e Event
+			if dec.Decode(&e) != nil {
+				return
+			}
+			select {
+			case events <- e:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return events, cancel, nil
+}
+
+// DebugMine mines n blocks, sending their rewards to address. The server
+// must have been constructed with WithDebug, or this will fail.
+func (c *Client) DebugMine(n int, address types.Address) (err error) {
+	err = c.c.POST("/debug/mine", DebugMineRequest{Blocks: n, Address: address}, nil)
+	return
+}
+
+// DebugReorg force-applies an alternative chain segment, as if it had been
+// received from a peer, to exercise reorg handling in tests. The server
+// must have been constructed with WithDebug, or this will fail.
+func (c *Client) DebugReorg(blocks []types.Block) (err error) {
+	err = c.c.POST("/debug/reorg", DebugReorgRequest{Blocks: blocks}, nil)
+	return
+}
+
 // NewClient returns a client that communicates with a explorer server listening
 // on the specified address.
 func NewClient(addr, password string) *Client {
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"go.sia.tech/core/consensus"
@@ -16,12 +18,15 @@ type (
 		Peers() []string
 		Connect(addr string) error
 		BroadcastTransaction(txn types.Transaction, dependsOn []types.Transaction)
+		BroadcastV2Transaction(txn types.V2Transaction)
 	}
 
 	// A TransactionPool can validate and relay unconfirmed transactions.
 	TransactionPool interface {
 		Transactions() []types.Transaction
 		AddTransaction(txn types.Transaction) error
+		V2Transactions() []types.V2Transaction
+		AddV2Transaction(txn types.V2Transaction) error
 	}
 
 	// A ChainManager manages blockchain state.
@@ -29,29 +34,94 @@ type (
 		TipState() consensus.State
 	}
 
+	// A DebugChainManager extends ChainManager with operations for
+	// deterministically advancing the chain, for use in tests. It is only
+	// required when the server is constructed with WithDebug.
+	DebugChainManager interface {
+		ChainManager
+		// MineBlocks mines n blocks, sending their rewards to address.
+		MineBlocks(n int, address types.Address) error
+		// ApplyReorg force-applies an alternative chain segment, as if it
+		// had been received from a peer, to exercise reorg handling.
+		ApplyReorg(blocks []types.Block) error
+	}
+
 	// An Explorer contains a database storing information about blocks, outputs,
 	// contracts.
 	Explorer interface {
 		SiacoinElement(id types.ElementID) (types.SiacoinElement, error)
 		SiafundElement(id types.ElementID) (types.SiafundElement, error)
 		FileContractElement(id types.ElementID) (types.FileContractElement, error)
+		V2FileContractElement(id types.ElementID) (types.V2FileContractElement, error)
 		ChainStats(index types.ChainIndex) (explorer.ChainStats, error)
 		ChainStatsLatest() (explorer.ChainStats, error)
 		SiacoinBalance(address types.Address) (types.Currency, error)
 		SiafundBalance(address types.Address) (uint64, error)
 		Transaction(id types.TransactionID) (types.Transaction, error)
+		V2Transaction(id types.TransactionID) (types.V2Transaction, error)
+		V2TransactionAttachments(id types.TransactionID) ([]types.Attestation, []byte, error)
 		UnspentSiacoinElements(address types.Address) ([]types.ElementID, error)
 		UnspentSiafundElements(address types.Address) ([]types.ElementID, error)
-		Transactions(address types.Address, amount, offset int) ([]types.TransactionID, error)
+		// Transactions and V2Transactions return a page of transaction IDs
+		// for address matching query, in descending chain order.
+		Transactions(address types.Address, query TransactionQuery) (TransactionPage, error)
+		V2Transactions(address types.Address, query TransactionQuery) (TransactionPage, error)
 		State(index types.ChainIndex) (context consensus.State, err error)
+
+		// SiacoinBalanceAt and SiafundBalanceAt return an address' balance
+		// as of the given chain index.
+		SiacoinBalanceAt(address types.Address, index types.ChainIndex) (types.Currency, error)
+		SiafundBalanceAt(address types.Address, index types.ChainIndex) (uint64, error)
+		// UnspentSiacoinElementsAt and UnspentSiafundElementsAt return the
+		// elements an address had unspent as of the given chain index, i.e.
+		// those created at or before the index and not yet spent at it.
+		UnspentSiacoinElementsAt(address types.Address, index types.ChainIndex) ([]types.ElementID, error)
+		UnspentSiafundElementsAt(address types.Address, index types.ChainIndex) ([]types.ElementID, error)
+		// ReorgEpoch returns a counter that increments every time the chain
+		// reorgs, allowing callers of the "at index" endpoints to detect
+		// whether a previously queried index has since been reorged away.
+		ReorgEpoch() (uint64, error)
+
+		// RichList returns the n addresses with the largest siacoin
+		// balance, ordered descending.
+		RichList(n int) ([]RichListEntry, error)
+		// SupplyDistribution buckets every address' siacoin balance into
+		// the ranges delimited by bounds and reports the Gini coefficient
+		// of the resulting distribution.
+		SupplyDistribution(bounds []types.Currency) ([]DistributionBucket, float64, error)
+		// AddressesForUnlockHash returns every address the explorer has
+		// observed that resolves to the given unlock hash template.
+		AddressesForUnlockHash(h types.Hash256) ([]types.Address, error)
+
+		// Subscribe registers a subscriber for the given categories,
+		// optionally replaying events recorded since the given resume
+		// token from the ring buffer. The returned channel is closed,
+		// and the subscriber dropped, if the caller falls too far
+		// behind to keep up with it.
+		Subscribe(categories []string, since *ResumeToken) (events <-chan Event, cancel func(), err error)
 	}
 )
 
 type server struct {
-	s  Syncer
-	e  Explorer
-	cm ChainManager
-	tp TransactionPool
+	s     Syncer
+	e     Explorer
+	cm    ChainManager
+	tp    TransactionPool
+	debug bool
+}
+
+// A ServerOption configures optional behavior of the handler returned by
+// NewServer.
+type ServerOption func(*server)
+
+// WithDebug mounts debug-only endpoints for deterministically mining blocks
+// and forcing reorgs, for use in tests. The chain manager passed to
+// NewServer must implement DebugChainManager or the debug endpoints will
+// fail. Never enable this in a production deployment: there is no
+// authentication beyond the server's own, and it lets any caller rewrite
+// the chain.
+func WithDebug() ServerOption {
+	return func(s *server) { s.debug = true }
 }
 
 func (s *server) txpoolBroadcastHandler(jc jape.Context) {
@@ -65,14 +135,30 @@ func (s *server) txpoolBroadcastHandler(jc jape.Context) {
 			return
 		}
 	}
-	if jc.Check("couldn't broadcast transaction dependency", s.tp.AddTransaction(tbr.Transaction)) != nil {
-		return
+	for _, txn := range tbr.V2DependsOn {
+		if jc.Check("couldn't broadcast v2 transaction dependency", s.tp.AddV2Transaction(txn)) != nil {
+			return
+		}
+	}
+	if tbr.Transaction != nil {
+		if jc.Check("couldn't broadcast transaction", s.tp.AddTransaction(*tbr.Transaction)) != nil {
+			return
+		}
+		s.s.BroadcastTransaction(*tbr.Transaction, tbr.DependsOn)
+	}
+	if tbr.V2Transaction != nil {
+		if jc.Check("couldn't broadcast v2 transaction", s.tp.AddV2Transaction(*tbr.V2Transaction)) != nil {
+			return
+		}
+		s.s.BroadcastV2Transaction(*tbr.V2Transaction)
 	}
-	s.s.BroadcastTransaction(tbr.Transaction, tbr.DependsOn)
 }
 
 func (s *server) txpoolTransactionsHandler(jc jape.Context) {
-	jc.Encode(s.tp.Transactions())
+	jc.Encode(TxpoolTransactionsResponse{
+		Transactions:   s.tp.Transactions(),
+		V2Transactions: s.tp.V2Transactions(),
+	})
 }
 
 func (s *server) syncerPeersHandler(jc jape.Context) {
@@ -135,6 +221,19 @@ func (s *server) elementContractHandler(jc jape.Context) {
 	jc.Encode(elem)
 }
 
+func (s *server) elementV2ContractHandler(jc jape.Context) {
+	var id types.ElementID
+	if jc.DecodeParam("id", &id) != nil {
+		return
+	}
+
+	elem, err := s.e.V2FileContractElement(id)
+	if jc.Check("failed to load v2 file contract element", err) != nil {
+		return
+	}
+	jc.Encode(elem)
+}
+
 func (s *server) chainStatsHandler(jc jape.Context) {
 	if jc.PathParam("index") == "tip" {
 		facts, err := s.e.ChainStatsLatest()
@@ -186,6 +285,9 @@ func (s *server) elementSearchHandler(jc jape.Context) {
 	} else if elem, err := s.e.FileContractElement(id); err == nil {
 		response.Type = "contract"
 		response.FileContractElement = elem
+	} else if elem, err := s.e.V2FileContractElement(id); err == nil {
+		response.Type = "v2contract"
+		response.V2FileContractElement = elem
 	}
 	jc.Encode(response)
 }
@@ -235,28 +337,151 @@ func (s *server) addressSiafundsHandler(jc jape.Context) {
 	jc.Encode(outputs)
 }
 
-func (s *server) addressTransactionsHandler(jc jape.Context) {
+// parseIndexOrTipParam parses the ":index" path parameter, treating the
+// literal "tip" as the chain manager's current tip.
+func (s *server) parseIndexOrTipParam(jc jape.Context) (types.ChainIndex, error) {
+	if jc.PathParam("index") == "tip" {
+		return s.cm.TipState().Index, nil
+	}
+	return types.ParseChainIndex(jc.PathParam("index"))
+}
+
+func (s *server) addressBalanceAtHandler(jc jape.Context) {
+	var address types.Address
+	if jc.DecodeParam("address", &address) != nil {
+		return
+	}
+
+	index, err := s.parseIndexOrTipParam(jc)
+	if jc.Check("failed to parse chain index", err) != nil {
+		return
+	}
+
+	// Capture the epoch before reading the balance: if a reorg lands in
+	// between, the epoch returned to the caller is at least as old as the
+	// data, so it still accurately flags the data as stale instead of
+	// masking a reorg that raced the read.
+	epoch, err := s.e.ReorgEpoch()
+	if jc.Check("failed to get reorg epoch", err) != nil {
+		return
+	}
+
+	scBalance, err := s.e.SiacoinBalanceAt(address, index)
+	if jc.Check("failed to get siacoin balance", err) != nil {
+		return
+	}
+
+	sfBalance, err := s.e.SiafundBalanceAt(address, index)
+	if jc.Check("failed to get siafund balance", err) != nil {
+		return
+	}
+
+	jc.Encode(ExplorerWalletBalanceAtResponse{scBalance, sfBalance, epoch})
+}
+
+func (s *server) addressSiacoinsAtHandler(jc jape.Context) {
+	var address types.Address
+	if jc.DecodeParam("address", &address) != nil {
+		return
+	}
+
+	index, err := s.parseIndexOrTipParam(jc)
+	if jc.Check("failed to parse chain index", err) != nil {
+		return
+	}
+
+	// Captured before the element read; see addressBalanceAtHandler.
+	epoch, err := s.e.ReorgEpoch()
+	if jc.Check("failed to get reorg epoch", err) != nil {
+		return
+	}
+
+	elements, err := s.e.UnspentSiacoinElementsAt(address, index)
+	if jc.Check("failed to get unspent siacoin elements", err) != nil {
+		return
+	}
+
+	jc.Encode(ExplorerElementsAtResponse{elements, epoch})
+}
+
+func (s *server) addressSiafundsAtHandler(jc jape.Context) {
 	var address types.Address
 	if jc.DecodeParam("address", &address) != nil {
 		return
 	}
 
-	var amount int
-	if jc.DecodeForm("amount", &amount) != nil {
+	index, err := s.parseIndexOrTipParam(jc)
+	if jc.Check("failed to parse chain index", err) != nil {
+		return
+	}
+
+	// Captured before the element read; see addressBalanceAtHandler.
+	epoch, err := s.e.ReorgEpoch()
+	if jc.Check("failed to get reorg epoch", err) != nil {
+		return
+	}
+
+	elements, err := s.e.UnspentSiafundElementsAt(address, index)
+	if jc.Check("failed to get unspent siafund elements", err) != nil {
+		return
+	}
+
+	jc.Encode(ExplorerElementsAtResponse{elements, epoch})
+}
+
+// decodeTransactionQuery parses a TransactionQuery from the request's query
+// string, writing an error response and returning ok=false on failure.
+func (s *server) decodeTransactionQuery(jc jape.Context) (q TransactionQuery, ok bool) {
+	if jc.DecodeForm("sinceHeight", &q.SinceHeight) != nil {
+		return q, false
+	}
+	if jc.DecodeForm("untilHeight", &q.UntilHeight) != nil {
+		return q, false
+	}
+	if jc.DecodeForm("sinceTime", &q.SinceTime) != nil {
+		return q, false
+	}
+	if jc.DecodeForm("untilTime", &q.UntilTime) != nil {
+		return q, false
+	}
+	if jc.DecodeForm("minAmount", &q.MinAmount) != nil {
+		return q, false
+	}
+	if jc.DecodeForm("maxAmount", &q.MaxAmount) != nil {
+		return q, false
+	}
+	if jc.DecodeForm("limit", &q.Limit) != nil {
+		return q, false
+	}
+	q.Kind = TransactionKind(jc.Request().URL.Query().Get("kind"))
+
+	if cur := jc.Request().URL.Query().Get("cursor"); cur != "" {
+		var c TransactionCursor
+		if jc.Check("failed to parse cursor", json.Unmarshal([]byte(cur), &c)) != nil {
+			return q, false
+		}
+		q.Cursor = &c
+	}
+	return q, true
+}
+
+func (s *server) addressTransactionsHandler(jc jape.Context) {
+	var address types.Address
+	if jc.DecodeParam("address", &address) != nil {
 		return
 	}
 
-	var offset int
-	if jc.DecodeForm("offset", &amount) != nil {
+	query, ok := s.decodeTransactionQuery(jc)
+	if !ok {
 		return
 	}
 
-	ids, err := s.e.Transactions(address, amount, offset)
+	page, err := s.e.Transactions(address, query)
 	if jc.Check("failed to get address' transactions", err) != nil {
 		return
 	}
 
-	jc.Encode(ids)
+	jc.Encode(page)
 }
 
 func (s *server) transactionHandler(jc jape.Context) {
@@ -272,6 +497,35 @@ func (s *server) transactionHandler(jc jape.Context) {
 	jc.Encode(txn)
 }
 
+func (s *server) v2TransactionHandler(jc jape.Context) {
+	var id types.TransactionID
+	if jc.DecodeParam("id", &id) != nil {
+		return
+	}
+
+	txn, err := s.e.V2Transaction(id)
+	if jc.Check("failed to load v2 transaction", err) != nil {
+		return
+	}
+	jc.Encode(txn)
+}
+
+func (s *server) v2TransactionAttachmentsHandler(jc jape.Context) {
+	var id types.TransactionID
+	if jc.DecodeParam("id", &id) != nil {
+		return
+	}
+
+	attestations, arbitraryData, err := s.e.V2TransactionAttachments(id)
+	if jc.Check("failed to load v2 transaction attachments", err) != nil {
+		return
+	}
+	jc.Encode(V2TransactionAttachmentsResponse{
+		Attestations:  attestations,
+		ArbitraryData: arbitraryData,
+	})
+}
+
 func (s *server) batchAddressesBalanceHandler(jc jape.Context) {
 	var addresses []types.Address
 	if jc.Decode(&addresses) != nil {
@@ -353,61 +607,236 @@ func (s *server) batchAddressesTransactionsHandler(jc jape.Context) {
 		return
 	}
 
-	var txns [][]types.Transaction
+	var results []ExplorerBatchTransactionsResponse
 	for _, etr := range etrs {
-		ids, err := s.e.Transactions(etr.Address, etr.Amount, etr.Offset)
+		page, err := s.e.Transactions(etr.Address, etr.Query)
 		if jc.Check("failed to load transactions", err) != nil {
 			return
 		}
 
 		var txnsList []types.Transaction
-		for _, id := range ids {
+		for _, id := range page.Items {
 			txn, err := s.e.Transaction(id)
 			if jc.Check("failed to load transaction", err) != nil {
 				return
 			}
 			txnsList = append(txnsList, txn)
 		}
-		txns = append(txns, txnsList)
+
+		v2page, err := s.e.V2Transactions(etr.Address, etr.V2Query)
+		if jc.Check("failed to load v2 transactions", err) != nil {
+			return
+		}
+
+		var v2txnsList []types.V2Transaction
+		for _, id := range v2page.Items {
+			txn, err := s.e.V2Transaction(id)
+			if jc.Check("failed to load v2 transaction", err) != nil {
+				return
+			}
+			v2txnsList = append(v2txnsList, txn)
+		}
+
+		results = append(results, ExplorerBatchTransactionsResponse{
+			Transactions:   txnsList,
+			V2Transactions: v2txnsList,
+			NextCursor:     page.NextCursor,
+			V2NextCursor:   v2page.NextCursor,
+		})
+	}
+	jc.Encode(results)
+}
+
+// defaultDistributionBounds buckets the supply by order of magnitude when
+// the caller doesn't supply its own bounds.
+var defaultDistributionBounds = []types.Currency{
+	types.Siacoins(1),
+	types.Siacoins(1e3),
+	types.Siacoins(1e6),
+	types.Siacoins(1e9),
+}
+
+func (s *server) statsRichListHandler(jc jape.Context) {
+	limit := 100
+	if jc.DecodeForm("limit", &limit) != nil {
+		return
+	}
+
+	entries, err := s.e.RichList(limit)
+	if jc.Check("failed to load rich list", err) != nil {
+		return
+	}
+	jc.Encode(entries)
+}
+
+func (s *server) statsDistributionHandler(jc jape.Context) {
+	bounds := append([]types.Currency(nil), defaultDistributionBounds...)
+	if raw := jc.Request().URL.Query().Get("bounds"); raw != "" {
+		bounds = nil
+		if jc.Check("failed to parse bounds", json.Unmarshal([]byte(raw), &bounds)) != nil {
+			return
+		}
+	}
+
+	buckets, gini, err := s.e.SupplyDistribution(bounds)
+	if jc.Check("failed to compute supply distribution", err) != nil {
+		return
+	}
+	jc.Encode(ExplorerDistributionResponse{Buckets: buckets, Gini: gini})
+}
+
+func (s *server) statsUnlockHashHandler(jc jape.Context) {
+	var h types.Hash256
+	if jc.DecodeParam("uh", &h) != nil {
+		return
+	}
+
+	addresses, err := s.e.AddressesForUnlockHash(h)
+	if jc.Check("failed to load addresses for unlock hash", err) != nil {
+		return
+	}
+	jc.Encode(addresses)
+}
+
+// subscribeHandler streams Events to the client as newline-delimited JSON
+// over a chunked HTTP response. It stays open until the client disconnects
+// or the subscription is dropped for falling behind.
+func (s *server) subscribeHandler(jc jape.Context) {
+	req := SubscribeRequest{
+		Categories: jc.Request().URL.Query()["category"],
+	}
+	if tok := jc.Request().URL.Query().Get("since"); tok != "" {
+		var since ResumeToken
+		if jc.Check("failed to parse since cursor", json.Unmarshal([]byte(tok), &since)) != nil {
+			return
+		}
+		req.Since = &since
+	}
+
+	events, cancel, err := s.e.Subscribe(req.Categories, req.Since)
+	if jc.Check("failed to subscribe", err) != nil {
+		return
+	}
+	defer cancel()
+
+	w := jc.ResponseWriter()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		jc.Check("failed to subscribe", errors.New("streaming not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if enc.Encode(event) != nil {
+				return
+			}
+			flusher.Flush()
+		case <-jc.Request().Context().Done():
+			return
+		}
+	}
+}
+
+// debugMineHandler mines req.Blocks blocks, sending their rewards to
+// req.Address. Only reachable when the server was constructed with
+// WithDebug.
+func (s *server) debugMineHandler(jc jape.Context) {
+	var req DebugMineRequest
+	if jc.Decode(&req) != nil {
+		return
+	}
+
+	dcm, ok := s.cm.(DebugChainManager)
+	if !ok {
+		jc.Check("debug mining unsupported", errors.New("chain manager does not implement DebugChainManager"))
+		return
+	}
+	jc.Check("failed to mine blocks", dcm.MineBlocks(req.Blocks, req.Address))
+}
+
+// debugReorgHandler force-applies an alternative chain segment, to
+// exercise reorg handling. Only reachable when the server was constructed
+// with WithDebug.
+func (s *server) debugReorgHandler(jc jape.Context) {
+	var req DebugReorgRequest
+	if jc.Decode(&req) != nil {
+		return
+	}
+
+	dcm, ok := s.cm.(DebugChainManager)
+	if !ok {
+		jc.Check("debug reorgs unsupported", errors.New("chain manager does not implement DebugChainManager"))
+		return
 	}
-	jc.Encode(txns)
+	jc.Check("failed to apply reorg", dcm.ApplyReorg(req.Blocks))
 }
 
-// NewServer returns an HTTP handler that serves the explorerd API.
-func NewServer(cm ChainManager, s Syncer, tp TransactionPool, e Explorer) http.Handler {
+// NewServer returns an HTTP handler that serves the explorerd API. The
+// debug endpoints are mounted only when opts includes WithDebug; otherwise
+// requests to them 404 rather than revealing their existence.
+func NewServer(cm ChainManager, s Syncer, tp TransactionPool, e Explorer, opts ...ServerOption) http.Handler {
 	srv := server{
 		cm: cm,
 		s:  s,
 		tp: tp,
 		e:  e,
 	}
-	return jape.Mux(map[string]jape.Handler{
+	for _, opt := range opts {
+		opt(&srv)
+	}
+
+	routes := map[string]jape.Handler{
 		"GET /txpool/transactions": srv.txpoolTransactionsHandler,
 		"POST /txpool/broadcast":   srv.txpoolBroadcastHandler,
 
 		"GET /syncer/peers":    srv.syncerPeersHandler,
 		"POST /syncer/connect": srv.syncerConnectHandler,
 
-		"GET /explorer/element/search/:id":   srv.elementSearchHandler,
-		"GET /explorer/element/siacoin/:id":  srv.elementSiacoinHandler,
-		"GET /explorer/element/siafund/:id":  srv.elementSiafundHandler,
-		"GET /explorer/element/contract/:id": srv.elementContractHandler,
+		"GET /explorer/element/search/:id":     srv.elementSearchHandler,
+		"GET /explorer/element/siacoin/:id":    srv.elementSiacoinHandler,
+		"GET /explorer/element/siafund/:id":    srv.elementSiafundHandler,
+		"GET /explorer/element/contract/:id":   srv.elementContractHandler,
+		"GET /explorer/element/v2contract/:id": srv.elementV2ContractHandler,
 
 		"GET /explorer/chain/:index":       srv.chainStatsHandler,
 		"GET /explorer/chain/:index/state": srv.chainStateHandler,
 
-		"GET /explorer/transaction/:id": srv.transactionHandler,
+		"GET /explorer/transaction/:id":                srv.transactionHandler,
+		"GET /explorer/v2/transaction/:id":             srv.v2TransactionHandler,
+		"GET /explorer/v2/transaction/:id/attachments": srv.v2TransactionAttachmentsHandler,
+
+		"GET /explorer/address/:address/balance":         srv.addressBalanceHandler,
+		"GET /explorer/address/:address/balance/:index":  srv.addressBalanceAtHandler,
+		"GET /explorer/address/:address/siacoins":        srv.addressSiacoinsHandler,
+		"GET /explorer/address/:address/siacoins/:index": srv.addressSiacoinsAtHandler,
+		"GET /explorer/address/:address/siafunds":        srv.addressSiafundsHandler,
+		"GET /explorer/address/:address/siafunds/:index": srv.addressSiafundsAtHandler,
+		"GET /explorer/address/:address/transactions":    srv.addressTransactionsHandler,
+
+		"GET /explorer/subscribe": srv.subscribeHandler,
 
-		"GET /explorer/address/:address/balance":      srv.addressBalanceHandler,
-		"GET /explorer/address/:address/siacoins":     srv.addressSiacoinsHandler,
-		"GET /explorer/address/:address/siafunds":     srv.addressSiacoinsHandler,
-		"GET /explorer/address/:address/transactions": srv.addressTransactionsHandler,
+		"GET /explorer/stats/richlist":       srv.statsRichListHandler,
+		"GET /explorer/stats/distribution":   srv.statsDistributionHandler,
+		"GET /explorer/stats/unlockhash/:uh": srv.statsUnlockHashHandler,
 
 		"POST /explorer/batch/addresses/balance":      srv.batchAddressesBalanceHandler,
 		"POST /explorer/batch/addresses/siacoins":     srv.batchAddressesSiacoinsHandler,
 		"POST /explorer/batch/addresses/siafunds":     srv.batchAddressesSiafundsHandler,
 		"POST /explorer/batch/addresses/transactions": srv.batchAddressesTransactionsHandler,
-	})
+	}
+	if srv.debug {
+		routes["POST /debug/mine"] = srv.debugMineHandler
+		routes["POST /debug/reorg"] = srv.debugReorgHandler
+	}
+	return jape.Mux(routes)
 }
 
 // AuthMiddleware enforces HTTP Basic Authentication on the provided handler.
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"go.sia.tech/core/consensus"
+	"go.sia.tech/core/types"
+	"go.sia.tech/explorer"
+)
+
+var errNotFound = errors.New("not found")
+
+// testSyncer is a no-op Syncer fake.
+type testSyncer struct{}
+
+func (testSyncer) Addr() string                                                { return "" }
+func (testSyncer) Peers() []string                                             { return nil }
+func (testSyncer) Connect(addr string) error                                   { return nil }
+func (testSyncer) BroadcastTransaction(types.Transaction, []types.Transaction) {}
+func (testSyncer) BroadcastV2Transaction(types.V2Transaction)                  {}
+
+// testTransactionPool is a no-op TransactionPool fake.
+type testTransactionPool struct{}
+
+func (testTransactionPool) Transactions() []types.Transaction          { return nil }
+func (testTransactionPool) AddTransaction(types.Transaction) error     { return nil }
+func (testTransactionPool) V2Transactions() []types.V2Transaction      { return nil }
+func (testTransactionPool) AddV2Transaction(types.V2Transaction) error { return nil }
+
+// testChainManager is a ChainManager/DebugChainManager fake that records
+// calls made against it.
+type testChainManager struct {
+	minedBlocks  int
+	minedAddress types.Address
+	reorgBlocks  []types.Block
+}
+
+func (*testChainManager) TipState() consensus.State { return consensus.State{} }
+
+func (cm *testChainManager) MineBlocks(n int, address types.Address) error {
+	cm.minedBlocks = n
+	cm.minedAddress = address
+	return nil
+}
+
+func (cm *testChainManager) ApplyReorg(blocks []types.Block) error {
+	cm.reorgBlocks = blocks
+	return nil
+}
+
+// testExplorer is an Explorer fake whose address-keyed lookups return
+// canned per-address results so handler tests can assert on routing.
+type testExplorer struct {
+	siacoinElements map[types.Address][]types.ElementID
+	siafundElements map[types.Address][]types.ElementID
+
+	// transactionsQuery/v2TransactionsQuery record the last query passed to
+	// Transactions/V2Transactions, so tests can assert the batch handler
+	// routed each address' legacy and v2 queries independently.
+	transactionsQuery   TransactionQuery
+	v2TransactionsQuery TransactionQuery
+}
+
+func (*testExplorer) SiacoinElement(types.ElementID) (types.SiacoinElement, error) {
+	return types.SiacoinElement{}, errNotFound
+}
+func (*testExplorer) SiafundElement(types.ElementID) (types.SiafundElement, error) {
+	return types.SiafundElement{}, errNotFound
+}
+func (*testExplorer) FileContractElement(types.ElementID) (types.FileContractElement, error) {
+	return types.FileContractElement{}, errNotFound
+}
+func (*testExplorer) V2FileContractElement(types.ElementID) (types.V2FileContractElement, error) {
+	return types.V2FileContractElement{}, errNotFound
+}
+func (*testExplorer) ChainStats(types.ChainIndex) (explorer.ChainStats, error) {
+	return explorer.ChainStats{}, nil
+}
+func (*testExplorer) ChainStatsLatest() (explorer.ChainStats, error) {
+	return explorer.ChainStats{}, nil
+}
+func (*testExplorer) SiacoinBalance(types.Address) (types.Currency, error) {
+	return types.Currency{}, nil
+}
+func (*testExplorer) SiafundBalance(types.Address) (uint64, error) { return 0, nil }
+func (*testExplorer) Transaction(types.TransactionID) (types.Transaction, error) {
+	return types.Transaction{}, nil
+}
+func (*testExplorer) V2Transaction(types.TransactionID) (types.V2Transaction, error) {
+	return types.V2Transaction{}, nil
+}
+func (*testExplorer) V2TransactionAttachments(types.TransactionID) ([]types.Attestation, []byte, error) {
+	return nil, nil, nil
+}
+func (e *testExplorer) UnspentSiacoinElements(address types.Address) ([]types.ElementID, error) {
+	return e.siacoinElements[address], nil
+}
+func (e *testExplorer) UnspentSiafundElements(address types.Address) ([]types.ElementID, error) {
+	return e.siafundElements[address], nil
+}
+func (e *testExplorer) Transactions(address types.Address, query TransactionQuery) (TransactionPage, error) {
+	e.transactionsQuery = query
+	return TransactionPage{}, nil
+}
+func (e *testExplorer) V2Transactions(address types.Address, query TransactionQuery) (TransactionPage, error) {
+	e.v2TransactionsQuery = query
+	return TransactionPage{}, nil
+}
+func (*testExplorer) State(types.ChainIndex) (consensus.State, error) { return consensus.State{}, nil }
+func (*testExplorer) SiacoinBalanceAt(types.Address, types.ChainIndex) (types.Currency, error) {
+	return types.Currency{}, nil
+}
+func (*testExplorer) SiafundBalanceAt(types.Address, types.ChainIndex) (uint64, error) { return 0, nil }
+func (*testExplorer) UnspentSiacoinElementsAt(types.Address, types.ChainIndex) ([]types.ElementID, error) {
+	return nil, nil
+}
+func (*testExplorer) UnspentSiafundElementsAt(types.Address, types.ChainIndex) ([]types.ElementID, error) {
+	return nil, nil
+}
+func (*testExplorer) ReorgEpoch() (uint64, error)           { return 0, nil }
+func (*testExplorer) RichList(int) ([]RichListEntry, error) { return nil, nil }
+func (*testExplorer) SupplyDistribution([]types.Currency) ([]DistributionBucket, float64, error) {
+	return nil, 0, nil
+}
+func (*testExplorer) AddressesForUnlockHash(types.Hash256) ([]types.Address, error) { return nil, nil }
+func (*testExplorer) Subscribe([]string, *ResumeToken) (<-chan Event, func(), error) {
+	return nil, func() {}, nil
+}
+
+func newTestServer(e *testExplorer, cm ChainManager, opts ...ServerOption) (*Client, func()) {
+	srv := httptest.NewServer(NewServer(cm, testSyncer{}, testTransactionPool{}, e, opts...))
+	return NewClient(srv.URL, ""), srv.Close
+}
+
+// TestAddressSiafundsRoute guards against the siafunds endpoint being wired
+// to the siacoins handler, as it once was.
+func TestAddressSiafundsRoute(t *testing.T) {
+	var addr types.Address
+
+	e := &testExplorer{
+		siacoinElements: map[types.Address][]types.ElementID{addr: {{}, {}}},
+		siafundElements: map[types.Address][]types.ElementID{addr: {{}}},
+	}
+	c, closeFn := newTestServer(e, &testChainManager{})
+	defer closeFn()
+
+	got, err := c.SiafundOutputs(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("siafunds endpoint returned %d elements, want 1 (siacoins leaking through would give 2)", len(got))
+	}
+}
+
+// TestBatchTransactionsIndependentQueries ensures a batch request's legacy
+// and v2 queries are routed to their respective Explorer methods rather
+// than both being fed the same TransactionQuery.
+func TestBatchTransactionsIndependentQueries(t *testing.T) {
+	e := &testExplorer{}
+	c, closeFn := newTestServer(e, &testChainManager{})
+	defer closeFn()
+
+	var addr types.Address
+	req := ExplorerTransactionsRequest{
+		Address: addr,
+		Query:   TransactionQuery{Limit: 1},
+		V2Query: TransactionQuery{Limit: 2},
+	}
+	if _, err := c.BatchTransactions([]ExplorerTransactionsRequest{req}); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.transactionsQuery.Limit != 1 {
+		t.Fatalf("legacy query reached Explorer.Transactions with Limit %d, want 1", e.transactionsQuery.Limit)
+	}
+	if e.v2TransactionsQuery.Limit != 2 {
+		t.Fatalf("v2 query reached Explorer.V2Transactions with Limit %d, want 2", e.v2TransactionsQuery.Limit)
+	}
+}
+
+// TestDebugMineRequiresOptIn ensures the debug endpoints are unreachable
+// unless the server was constructed with WithDebug.
+func TestDebugMineRequiresOptIn(t *testing.T) {
+	e := &testExplorer{}
+	c, closeFn := newTestServer(e, &testChainManager{})
+	defer closeFn()
+
+	if err := c.DebugMine(1, types.Address{}); err == nil {
+		t.Fatal("expected debug/mine to fail when WithDebug was not passed to NewServer")
+	}
+}
+
+// TestDebugMineWithOptIn exercises WithDebug end to end against a
+// DebugChainManager fake.
+func TestDebugMineWithOptIn(t *testing.T) {
+	e := &testExplorer{}
+	cm := &testChainManager{}
+	c, closeFn := newTestServer(e, cm, WithDebug())
+	defer closeFn()
+
+	var addr types.Address
+	if err := c.DebugMine(5, addr); err != nil {
+		t.Fatal(err)
+	}
+	if cm.minedBlocks != 5 {
+		t.Fatalf("got MineBlocks(%d, ...), want MineBlocks(5, ...)", cm.minedBlocks)
+	}
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"go.sia.tech/core/types"
+)
+
+// Subscription categories accepted by the /explorer/subscribe endpoint. An
+// "address:<addr>" category (see AddressCategory) limits events to those
+// touching a specific address.
+const (
+	CategoryBlocks = "blocks"
+	CategoryReorgs = "reorgs"
+	CategoryTxpool = "txpool"
+)
+
+// AddressCategory returns the subscription category for events touching
+// addr.
+func AddressCategory(addr types.Address) string {
+	return "address:" + addr.String()
+}
+
+// A ResumeToken identifies a point in the event stream so a reconnecting
+// subscriber can resume "since" it without missing events.
+type ResumeToken struct {
+	Index types.ChainIndex `json:"index"`
+	Seq   uint64           `json:"seq"`
+}
+
+// SubscribeRequest is the request type for the /explorer/subscribe
+// endpoint.
+type SubscribeRequest struct {
+	Categories []string     `json:"categories"`
+	Since      *ResumeToken `json:"since,omitempty"`
+}
+
+// A ChainUpdate is a single block applied to or reverted from the chain.
+type ChainUpdate struct {
+	Index types.ChainIndex `json:"index"`
+	Block types.Block      `json:"block"`
+}
+
+// An Event is a single update delivered to a subscriber of
+// /explorer/subscribe. Applied and Reverted mirror the
+// ConsensusUpdatesResponse pattern: Reverted carries the full payload of
+// what was reverted so a downstream indexer can undo it without
+// re-fetching state that the reorg already removed. Transaction and
+// V2Transaction are populated for txpool events instead.
+type Event struct {
+	Category      string               `json:"category"`
+	Token         ResumeToken          `json:"token"`
+	Applied       []ChainUpdate        `json:"applied,omitempty"`
+	Reverted      []ChainUpdate        `json:"reverted,omitempty"`
+	Transaction   *types.Transaction   `json:"transaction,omitempty"`
+	V2Transaction *types.V2Transaction `json:"v2Transaction,omitempty"`
+}
+
+// TxpoolBroadcastRequest is the request type for the /txpool/broadcast
+// endpoint. Transaction and V2Transaction are independent and either, both,
+// or neither may be set.
+type TxpoolBroadcastRequest struct {
+	DependsOn     []types.Transaction   `json:"dependsOn"`
+	Transaction   *types.Transaction    `json:"transaction,omitempty"`
+	V2DependsOn   []types.V2Transaction `json:"v2DependsOn,omitempty"`
+	V2Transaction *types.V2Transaction  `json:"v2Transaction,omitempty"`
+}
+
+// TxpoolTransactionsResponse is the response type for the
+// /txpool/transactions endpoint.
+type TxpoolTransactionsResponse struct {
+	Transactions   []types.Transaction   `json:"transactions"`
+	V2Transactions []types.V2Transaction `json:"v2Transactions"`
+}
+
+// SyncerPeerResponse is the response type for the /syncer/peers endpoint.
+type SyncerPeerResponse struct {
+	NetAddress string `json:"netAddress"`
+}
+
+// ExplorerSearchResponse is the response type for the
+// /explorer/element/search/:id endpoint.
+type ExplorerSearchResponse struct {
+	Type                  string                      `json:"type"`
+	SiacoinElement        types.SiacoinElement        `json:"siacoinElement,omitempty"`
+	SiafundElement        types.SiafundElement        `json:"siafundElement,omitempty"`
+	FileContractElement   types.FileContractElement   `json:"fileContractElement,omitempty"`
+	V2FileContractElement types.V2FileContractElement `json:"v2FileContractElement,omitempty"`
+}
+
+// ExplorerWalletBalanceResponse is the response type for the
+// /explorer/address/:address/balance endpoint.
+type ExplorerWalletBalanceResponse struct {
+	SiacoinBalance types.Currency `json:"siacoinBalance"`
+	SiafundBalance uint64         `json:"siafundBalance"`
+}
+
+// ExplorerWalletBalanceAtResponse is the response type for the
+// /explorer/address/:address/balance/:index endpoint. ReorgEpoch lets a
+// caller detect whether the chain index it queried has since been reorged
+// away, in which case the balance should be treated as stale.
+type ExplorerWalletBalanceAtResponse struct {
+	SiacoinBalance types.Currency `json:"siacoinBalance"`
+	SiafundBalance uint64         `json:"siafundBalance"`
+	ReorgEpoch     uint64         `json:"reorgEpoch"`
+}
+
+// ExplorerElementsAtResponse is the response type for the
+// /explorer/address/:address/siacoins/:index and
+// /explorer/address/:address/siafunds/:index endpoints. ReorgEpoch lets a
+// caller detect whether the chain index it queried has since been reorged
+// away, in which case the element set should be treated as stale.
+type ExplorerElementsAtResponse struct {
+	Elements   []types.ElementID `json:"elements"`
+	ReorgEpoch uint64            `json:"reorgEpoch"`
+}
+
+// A TransactionKind filters a TransactionQuery to transactions of a
+// particular relationship to the queried address.
+type TransactionKind string
+
+// TransactionKind values accepted by TransactionQuery.Kind. The zero value,
+// TransactionKindAny, applies no filter.
+const (
+	TransactionKindAny      TransactionKind = ""
+	TransactionKindSend     TransactionKind = "send"
+	TransactionKindReceive  TransactionKind = "receive"
+	TransactionKindContract TransactionKind = "contract"
+)
+
+// A TransactionCursor identifies a transaction's position within an
+// address' history, for resuming a TransactionQuery where a previous page
+// left off.
+type TransactionCursor struct {
+	Height  uint64 `json:"height"`
+	TxIndex int    `json:"txIndex"`
+}
+
+// A TransactionQuery filters and paginates an address' transaction history.
+// The query can be bounded either by block height (SinceHeight/UntilHeight)
+// or by unix timestamp (SinceTime/UntilTime); a zero value for a given
+// field leaves that bound unset. MinAmount and MaxAmount bound the
+// siacoins transferred; the zero Currency means unbounded. Cursor resumes
+// a previous page; Limit caps the number of items returned.
+type TransactionQuery struct {
+	Cursor      *TransactionCursor `json:"cursor,omitempty"`
+	SinceHeight uint64             `json:"sinceHeight,omitempty"`
+	UntilHeight uint64             `json:"untilHeight,omitempty"`
+	SinceTime   int64              `json:"sinceTime,omitempty"`
+	UntilTime   int64              `json:"untilTime,omitempty"`
+	MinAmount   types.Currency     `json:"minAmount,omitempty"`
+	MaxAmount   types.Currency     `json:"maxAmount,omitempty"`
+	Kind        TransactionKind    `json:"kind,omitempty"`
+	Limit       int                `json:"limit,omitempty"`
+}
+
+// A TransactionPage is a page of transaction IDs matching a TransactionQuery.
+// NextCursor is nil once the query has no further results.
+type TransactionPage struct {
+	Items      []types.TransactionID `json:"items"`
+	NextCursor *TransactionCursor    `json:"nextCursor,omitempty"`
+}
+
+// ExplorerTransactionsRequest is the request type used by the batch
+// transactions endpoint to look up transactions for a single address.
+// Query and V2Query are independent so a poller can resume the legacy and
+// v2 histories from their own cursors once the two diverge.
+type ExplorerTransactionsRequest struct {
+	Address types.Address    `json:"address"`
+	Query   TransactionQuery `json:"query"`
+	V2Query TransactionQuery `json:"v2Query"`
+}
+
+// ExplorerBatchTransactionsResponse is the response type for a single
+// address in the batch transactions endpoint, carrying both legacy and v2
+// transactions for that address, along with cursors for polling each on a
+// subsequent call.
+type ExplorerBatchTransactionsResponse struct {
+	Transactions   []types.Transaction   `json:"transactions"`
+	V2Transactions []types.V2Transaction `json:"v2Transactions"`
+	NextCursor     *TransactionCursor    `json:"nextCursor,omitempty"`
+	V2NextCursor   *TransactionCursor    `json:"v2NextCursor,omitempty"`
+}
+
+// V2TransactionAttachmentsResponse is the response type for the
+// /explorer/v2/transaction/:id/attachments endpoint.
+type V2TransactionAttachmentsResponse struct {
+	Attestations  []types.Attestation `json:"attestations"`
+	ArbitraryData []byte              `json:"arbitraryData"`
+}
+
+// DebugMineRequest is the request type for the /debug/mine endpoint. It is
+// only mounted when the server is constructed with WithDebug.
+type DebugMineRequest struct {
+	Blocks  int           `json:"blocks"`
+	Address types.Address `json:"address"`
+}
+
+// DebugReorgRequest is the request type for the /debug/reorg endpoint. It
+// is only mounted when the server is constructed with WithDebug.
+type DebugReorgRequest struct {
+	Blocks []types.Block `json:"blocks"`
+}
+
+// A RichListEntry is a single address' balance as reported by the
+// /explorer/stats/richlist endpoint.
+type RichListEntry struct {
+	Address        types.Address  `json:"address"`
+	SiacoinBalance types.Currency `json:"siacoinBalance"`
+}
+
+// A DistributionBucket reports how many addresses hold a siacoin balance in
+// [Min, Max).
+type DistributionBucket struct {
+	Min   types.Currency `json:"min"`
+	Max   types.Currency `json:"max"`
+	Count int            `json:"count"`
+}
+
+// ExplorerDistributionResponse is the response type for the
+// /explorer/stats/distribution endpoint.
+type ExplorerDistributionResponse struct {
+	Buckets []DistributionBucket `json:"buckets"`
+	Gini    float64              `json:"gini"`
+}